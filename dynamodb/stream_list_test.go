@@ -0,0 +1,63 @@
+package dynamodb
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestListStreamsPages(t *testing.T) {
+	pages := []*listStreamsResponse{
+		{
+			LastEvaluatedStreamArn: "arn:stream-2",
+			Streams:                []StreamSpec{{StreamArn: "arn:stream-1", TableName: "t"}},
+		},
+		{
+			LastEvaluatedStreamArn: "",
+			Streams:                []StreamSpec{{StreamArn: "arn:stream-2", TableName: "t"}},
+		},
+	}
+
+	var calls []string
+	fetch := func(exclusiveStartStreamArn string) (*listStreamsResponse, error) {
+		calls = append(calls, exclusiveStartStreamArn)
+		return pages[len(calls)-1], nil
+	}
+
+	streams, err := listStreamsPages(fetch)
+	if err != nil {
+		t.Fatalf("listStreamsPages: %v", err)
+	}
+
+	wantCalls := []string{"", "arn:stream-2"}
+	if !reflect.DeepEqual(calls, wantCalls) {
+		t.Errorf("calls = %v, want %v", calls, wantCalls)
+	}
+
+	wantArns := []string{"arn:stream-1", "arn:stream-2"}
+	var gotArns []string
+	for _, stream := range streams {
+		gotArns = append(gotArns, stream.StreamArn)
+	}
+	if !reflect.DeepEqual(gotArns, wantArns) {
+		t.Errorf("stream arns = %v, want %v", gotArns, wantArns)
+	}
+}
+
+func TestListStreamsPagesSinglePage(t *testing.T) {
+	calls := 0
+	fetch := func(exclusiveStartStreamArn string) (*listStreamsResponse, error) {
+		calls++
+		return &listStreamsResponse{Streams: []StreamSpec{{StreamArn: "arn:only"}}}, nil
+	}
+
+	streams, err := listStreamsPages(fetch)
+	if err != nil {
+		t.Fatalf("listStreamsPages: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("fetch called %d times, want 1", calls)
+	}
+	if len(streams) != 1 || streams[0].StreamArn != "arn:only" {
+		t.Errorf("streams = %+v, want a single arn:only entry", streams)
+	}
+}
@@ -0,0 +1,73 @@
+package dynamodb
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDescribeStreamPages(t *testing.T) {
+	pages := []*StreamDescriptionT{
+		{
+			LastEvaluatedShardId: "shard-2",
+			Shards:               []ShardT{{ShardId: "shard-1"}},
+		},
+		{
+			LastEvaluatedShardId: "",
+			Shards:               []ShardT{{ShardId: "shard-2"}},
+		},
+	}
+
+	var calls []string
+	fetch := func(exclusiveStartShardId string) (*StreamDescriptionT, error) {
+		calls = append(calls, exclusiveStartShardId)
+		page := pages[len(calls)-1]
+		return page, nil
+	}
+
+	desc, err := describeStreamPages(fetch)
+	if err != nil {
+		t.Fatalf("describeStreamPages: %v", err)
+	}
+
+	wantCalls := []string{"", "shard-2"}
+	if !reflect.DeepEqual(calls, wantCalls) {
+		t.Errorf("calls = %v, want %v", calls, wantCalls)
+	}
+
+	wantShardIds := []string{"shard-1", "shard-2"}
+	var gotShardIds []string
+	for _, shard := range desc.Shards {
+		gotShardIds = append(gotShardIds, shard.ShardId)
+	}
+	if !reflect.DeepEqual(gotShardIds, wantShardIds) {
+		t.Errorf("shard ids = %v, want %v", gotShardIds, wantShardIds)
+	}
+	if desc.LastEvaluatedShardId != "" {
+		t.Errorf("LastEvaluatedShardId = %q, want empty after merging all pages", desc.LastEvaluatedShardId)
+	}
+}
+
+func TestStreamDescriptionTShardHelpers(t *testing.T) {
+	desc := &StreamDescriptionT{
+		Shards: []ShardT{
+			{ShardId: "parent", SequenceNumberRange: SequenceNumberRangeT{EndingSequenceNumber: "100"}},
+			{ShardId: "child-a", ParentShardId: "parent"},
+			{ShardId: "child-b", ParentShardId: "parent"},
+		},
+	}
+
+	open := desc.OpenShards()
+	if len(open) != 2 {
+		t.Fatalf("len(OpenShards()) = %d, want 2", len(open))
+	}
+
+	closed := desc.ClosedShards()
+	if len(closed) != 1 || closed[0].ShardId != "parent" {
+		t.Fatalf("ClosedShards() = %+v, want just the parent shard", closed)
+	}
+
+	children := desc.Children("parent")
+	if len(children) != 2 {
+		t.Fatalf("len(Children(\"parent\")) = %d, want 2", len(children))
+	}
+}
@@ -0,0 +1,361 @@
+package dynamodb
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// Checkpointer persists the sequence number of the last record a
+// StreamSubscriber has successfully delivered for a given stream/shard
+// pair, so that consumption can resume where it left off after a
+// restart.
+type Checkpointer interface {
+	// GetCheckpoint returns the last checkpointed sequence number for
+	// the shard, or "" if the shard has never been checkpointed.
+	GetCheckpoint(streamId, shardId string) (string, error)
+
+	// SetCheckpoint records the sequence number of the last record
+	// processed for the shard.
+	SetCheckpoint(streamId, shardId, sequenceNumber string) error
+}
+
+// MemoryCheckpointer is a Checkpointer that keeps checkpoints in memory.
+// Checkpoints do not survive a process restart; it is mainly useful for
+// tests and short-lived consumers.
+type MemoryCheckpointer struct {
+	mu          sync.Mutex
+	checkpoints map[string]string
+}
+
+func NewMemoryCheckpointer() *MemoryCheckpointer {
+	return &MemoryCheckpointer{checkpoints: make(map[string]string)}
+}
+
+func (c *MemoryCheckpointer) GetCheckpoint(streamId, shardId string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.checkpoints[checkpointKey(streamId, shardId)], nil
+}
+
+func (c *MemoryCheckpointer) SetCheckpoint(streamId, shardId, sequenceNumber string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checkpoints[checkpointKey(streamId, shardId)] = sequenceNumber
+	return nil
+}
+
+func checkpointKey(streamId, shardId string) string {
+	return streamId + "/" + shardId
+}
+
+// DynamoDBCheckpointer persists checkpoints as items in a DynamoDB table,
+// keyed by the hash key "<streamId>/<shardId>". The table must have been
+// created with a string hash key named KeyAttr (default
+// "ShardCheckpointId") and no range key.
+type DynamoDBCheckpointer struct {
+	Table     *Table
+	KeyAttr   string
+	ValueAttr string
+}
+
+// NewDynamoDBCheckpointer returns a DynamoDBCheckpointer that stores
+// checkpoints in table.
+func NewDynamoDBCheckpointer(table *Table) *DynamoDBCheckpointer {
+	return &DynamoDBCheckpointer{
+		Table:     table,
+		KeyAttr:   "ShardCheckpointId",
+		ValueAttr: "SequenceNumber",
+	}
+}
+
+func (c *DynamoDBCheckpointer) GetCheckpoint(streamId, shardId string) (string, error) {
+	attrs, err := c.Table.GetItem(&Key{HashKey: checkpointKey(streamId, shardId)})
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return "", nil
+		}
+		return "", err
+	}
+	if attr, ok := attrs[c.ValueAttr]; ok {
+		return attr.Value, nil
+	}
+	return "", nil
+}
+
+func (c *DynamoDBCheckpointer) SetCheckpoint(streamId, shardId, sequenceNumber string) error {
+	attrs := []Attribute{
+		*NewStringAttribute(c.KeyAttr, checkpointKey(streamId, shardId)),
+		*NewStringAttribute(c.ValueAttr, sequenceNumber),
+	}
+	_, err := c.Table.PutItem(checkpointKey(streamId, shardId), "", attrs)
+	return err
+}
+
+// StreamSubscriber is a high-level consumer for a DynamoDB Stream. It
+// periodically calls DescribeStream to discover shards (including ones
+// created after the subscription started), spawns one goroutine per
+// open shard to drive GetShardIterator/GetRecords, and delivers records
+// from every shard on a single *RecordT channel. A child shard is only
+// consumed once its parent has been fully read, so a parent's records
+// are always delivered before its child's; records from unrelated
+// shards are delivered by concurrent goroutines and may interleave in
+// any order.
+//
+// This is the pattern most callers otherwise hand-roll on top of the
+// raw GetRecords API; it plays the same role as the Kinesis Client
+// Library does for Kinesis streams.
+type StreamSubscriber struct {
+	Table        *Table
+	StreamId     string
+	Checkpointer Checkpointer
+
+	// DiscoveryInterval controls how often DescribeStream is polled for
+	// new shards. Defaults to 30 seconds.
+	DiscoveryInterval time.Duration
+
+	// PollInterval controls how long a shard goroutine sleeps after an
+	// empty GetRecords response or a ProvisionedThroughputExceeded
+	// error before retrying. Defaults to 5 seconds.
+	PollInterval time.Duration
+
+	mu       sync.Mutex
+	finished map[string]bool
+	started  map[string]bool
+}
+
+// Subscribe starts consuming the stream and returns a channel of
+// records and a channel of errors encountered by shard consumers.
+// Closing stop causes all shard goroutines to exit and both returned
+// channels to be closed.
+func (s *StreamSubscriber) Subscribe(stop <-chan struct{}) (<-chan *RecordT, <-chan error) {
+	if s.DiscoveryInterval == 0 {
+		s.DiscoveryInterval = 30 * time.Second
+	}
+	if s.PollInterval == 0 {
+		s.PollInterval = 5 * time.Second
+	}
+	s.finished = make(map[string]bool)
+	s.started = make(map[string]bool)
+
+	records := make(chan *RecordT)
+	errs := make(chan error)
+
+	var wg sync.WaitGroup
+	go func() {
+		ticker := time.NewTicker(s.DiscoveryInterval)
+		defer ticker.Stop()
+
+		for {
+			shards, err := s.discoverShards()
+			if err != nil {
+				select {
+				case errs <- err:
+				case <-stop:
+					wg.Wait()
+					close(records)
+					close(errs)
+					return
+				}
+			} else {
+				s.seedFinishedForOrphans(shards)
+				for _, shard := range shards {
+					s.maybeStartShard(&wg, shard, records, errs, stop)
+				}
+			}
+
+			select {
+			case <-stop:
+				wg.Wait()
+				close(records)
+				close(errs)
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return records, errs
+}
+
+// discoverShards fetches the full shard list for the stream.
+func (s *StreamSubscriber) discoverShards() ([]ShardT, error) {
+	desc, err := s.Table.DescribeStreamAll(s.StreamId)
+	if err != nil {
+		return nil, err
+	}
+	return desc.Shards, nil
+}
+
+// seedFinishedForOrphans marks a shard as finished if it is referenced
+// as some shard's ParentShardId but is not itself present in the
+// current shard set. This happens for ordinary reasons, e.g. the
+// parent has aged out of the stream's retention window, so DescribeStream
+// simply stops returning it. Without this, maybeStartShard would wait
+// forever for a parent that this subscriber will never get a chance to
+// start, and its children (and their descendants) would never be
+// consumed.
+func (s *StreamSubscriber) seedFinishedForOrphans(shards []ShardT) {
+	present := make(map[string]bool, len(shards))
+	for _, shard := range shards {
+		present[shard.ShardId] = true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, shard := range shards {
+		if shard.ParentShardId != "" && !present[shard.ParentShardId] {
+			s.finished[shard.ParentShardId] = true
+		}
+	}
+}
+
+// canStartLocked reports whether shard is eligible to start: it hasn't
+// already been started, and its parent (if any) is marked finished.
+// A parent missing from the current shard set counts as finished only
+// once seedFinishedForOrphans has marked it. Callers must hold s.mu.
+func (s *StreamSubscriber) canStartLocked(shard ShardT) bool {
+	if s.started[shard.ShardId] {
+		return false
+	}
+	return shard.ParentShardId == "" || s.finished[shard.ParentShardId]
+}
+
+// shardOutcome describes why a shard's consumer goroutine exited.
+type shardOutcome int
+
+const (
+	// The shard was read all the way to the end of its
+	// SequenceNumberRange; its children can now start.
+	shardDrained shardOutcome = iota
+
+	// Subscribe's stop channel was closed; the whole subscriber is
+	// shutting down.
+	shardStopped
+
+	// A non-throttling error aborted consumption before the shard was
+	// drained. The shard was not fully read, so it must not be treated
+	// as finished, and it must be retried rather than abandoned.
+	shardFailed
+)
+
+// maybeStartShard starts a goroutine for shard unless it has already
+// been started or its parent has not yet been fully consumed.
+func (s *StreamSubscriber) maybeStartShard(wg *sync.WaitGroup, shard ShardT, records chan<- *RecordT, errs chan<- error, stop <-chan struct{}) {
+	s.mu.Lock()
+	if !s.canStartLocked(shard) {
+		s.mu.Unlock()
+		return
+	}
+	s.started[shard.ShardId] = true
+	s.mu.Unlock()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		outcome := s.consumeShard(shard, records, errs, stop)
+
+		s.mu.Lock()
+		s.recordShardOutcomeLocked(shard, outcome)
+		s.mu.Unlock()
+	}()
+}
+
+// recordShardOutcomeLocked updates finished/started for shard once its
+// consumer goroutine has exited. A drained shard is marked finished so
+// its children become eligible to start; a failed shard is instead
+// cleared from started so the next discovery tick restarts it (from its
+// last checkpoint) rather than abandoning it and silently unblocking
+// its children before the parent was actually fully read. Callers must
+// hold s.mu.
+func (s *StreamSubscriber) recordShardOutcomeLocked(shard ShardT, outcome shardOutcome) {
+	switch outcome {
+	case shardDrained:
+		s.finished[shard.ShardId] = true
+	case shardFailed:
+		delete(s.started, shard.ShardId)
+	case shardStopped:
+		// Subscriber is shutting down; leave started/finished as-is.
+	}
+}
+
+// consumeShard drives GetShardIterator/GetRecords for a single shard
+// until the shard is closed and fully drained, an unrecoverable error
+// occurs, or stop is closed.
+func (s *StreamSubscriber) consumeShard(shard ShardT, records chan<- *RecordT, errs chan<- error, stop <-chan struct{}) shardOutcome {
+	iterator, err := s.startingIterator(shard)
+	if err != nil {
+		select {
+		case errs <- err:
+		case <-stop:
+			return shardStopped
+		}
+		return shardFailed
+	}
+
+	for iterator != "" {
+		select {
+		case <-stop:
+			return shardStopped
+		default:
+		}
+
+		next, recs, err := s.Table.GetRecords(iterator)
+		if err != nil {
+			if isThrottlingError(err) {
+				time.Sleep(s.PollInterval)
+				continue
+			}
+			select {
+			case errs <- err:
+			case <-stop:
+				return shardStopped
+			}
+			return shardFailed
+		}
+
+		for _, rec := range recs {
+			select {
+			case records <- rec:
+			case <-stop:
+				return shardStopped
+			}
+			if s.Checkpointer != nil {
+				if err := s.Checkpointer.SetCheckpoint(s.StreamId, shard.ShardId, rec.DynamoDB.SequenceNumber); err != nil {
+					select {
+					case errs <- err:
+					case <-stop:
+						return shardStopped
+					}
+				}
+			}
+		}
+
+		if len(recs) == 0 {
+			time.Sleep(s.PollInterval)
+		}
+		iterator = next
+	}
+
+	return shardDrained
+}
+
+// startingIterator returns the shard iterator to begin consuming shard
+// from: right after the last checkpointed sequence number if one
+// exists, or TRIM_HORIZON otherwise.
+func (s *StreamSubscriber) startingIterator(shard ShardT) (string, error) {
+	if s.Checkpointer != nil {
+		seq, err := s.Checkpointer.GetCheckpoint(s.StreamId, shard.ShardId)
+		if err != nil {
+			return "", err
+		}
+		if seq != "" {
+			return s.Table.GetShardIteratorWithSeqNumber(s.StreamId, shard.ShardId, ShardIteratorAfterSequenceNumber, seq)
+		}
+	}
+	return s.Table.GetShardIterator(s.StreamId, shard.ShardId, ShardIteratorTrimHorizon)
+}
+
+func isThrottlingError(err error) bool {
+	return strings.Contains(err.Error(), "ProvisionedThroughputExceededException")
+}
@@ -0,0 +1,111 @@
+package dynamodb
+
+import "testing"
+
+func TestSeedFinishedForOrphans(t *testing.T) {
+	s := &StreamSubscriber{finished: map[string]bool{}, started: map[string]bool{}}
+
+	shards := []ShardT{
+		{ShardId: "child-1", ParentShardId: "trimmed-parent"},
+		{ShardId: "child-2", ParentShardId: "present-parent"},
+		{ShardId: "present-parent"},
+	}
+	s.seedFinishedForOrphans(shards)
+
+	if !s.finished["trimmed-parent"] {
+		t.Fatal("expected parent missing from the shard set to be marked finished")
+	}
+	if s.finished["present-parent"] {
+		t.Fatal("did not expect a parent still in the shard set to be marked finished")
+	}
+}
+
+func TestCanStartLocked(t *testing.T) {
+	s := &StreamSubscriber{finished: map[string]bool{}, started: map[string]bool{}}
+
+	root := ShardT{ShardId: "root"}
+	if !s.canStartLocked(root) {
+		t.Fatal("expected a shard with no parent to be startable")
+	}
+
+	child := ShardT{ShardId: "child", ParentShardId: "root"}
+	if s.canStartLocked(child) {
+		t.Fatal("expected child to wait for its unfinished parent")
+	}
+
+	s.finished["root"] = true
+	if !s.canStartLocked(child) {
+		t.Fatal("expected child to be startable once its parent is finished")
+	}
+
+	s.started["child"] = true
+	if s.canStartLocked(child) {
+		t.Fatal("expected an already-started shard not to be restarted")
+	}
+}
+
+func TestOrphanedParentUnblocksChild(t *testing.T) {
+	s := &StreamSubscriber{finished: map[string]bool{}, started: map[string]bool{}}
+
+	shards := []ShardT{
+		{ShardId: "child", ParentShardId: "trimmed-parent"},
+	}
+	s.seedFinishedForOrphans(shards)
+
+	if !s.canStartLocked(shards[0]) {
+		t.Fatal("expected child of a parent no longer in the shard set to be startable")
+	}
+}
+
+func TestRecordShardOutcomeLockedFailedShardIsRetryable(t *testing.T) {
+	s := &StreamSubscriber{finished: map[string]bool{}, started: map[string]bool{"shard-1": true}}
+	shard := ShardT{ShardId: "shard-1"}
+
+	s.recordShardOutcomeLocked(shard, shardFailed)
+
+	if s.started["shard-1"] {
+		t.Fatal("expected a failed shard to be cleared from started so it can be retried")
+	}
+	if s.finished["shard-1"] {
+		t.Fatal("did not expect a failed (not fully drained) shard to be marked finished")
+	}
+}
+
+func TestRecordShardOutcomeLockedDrainedShardFinishes(t *testing.T) {
+	s := &StreamSubscriber{finished: map[string]bool{}, started: map[string]bool{"shard-1": true}}
+	shard := ShardT{ShardId: "shard-1"}
+
+	s.recordShardOutcomeLocked(shard, shardDrained)
+
+	if !s.finished["shard-1"] {
+		t.Fatal("expected a drained shard to be marked finished so its children can start")
+	}
+	if !s.started["shard-1"] {
+		t.Fatal("did not expect a drained shard to be cleared from started")
+	}
+}
+
+func TestRecordShardOutcomeLockedStoppedShardIsLeftAlone(t *testing.T) {
+	s := &StreamSubscriber{finished: map[string]bool{}, started: map[string]bool{"shard-1": true}}
+	shard := ShardT{ShardId: "shard-1"}
+
+	s.recordShardOutcomeLocked(shard, shardStopped)
+
+	if !s.started["shard-1"] {
+		t.Fatal("did not expect a stopped shard's started flag to be cleared")
+	}
+	if s.finished["shard-1"] {
+		t.Fatal("did not expect a stopped shard to be marked finished")
+	}
+}
+
+func TestFailedParentDoesNotUnblockChild(t *testing.T) {
+	s := &StreamSubscriber{finished: map[string]bool{}, started: map[string]bool{"parent": true}}
+	child := ShardT{ShardId: "child", ParentShardId: "parent"}
+
+	s.recordShardOutcomeLocked(ShardT{ShardId: "parent"}, shardFailed)
+
+	if s.canStartLocked(child) {
+		t.Fatal("expected child not to start while its parent was never fully drained")
+	}
+}
@@ -2,7 +2,10 @@ package dynamodb
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
+
 	"github.com/crowdmob/goamz/dynamodb/dynamizer"
 )
 
@@ -25,6 +28,9 @@ const (
 	// so that you always read the most recent data in the shard.
 	ShardIteratorLatest ShardIteratorType = "LATEST"
 
+	// Start reading at the first record at or after the given Timestamp.
+	ShardIteratorAtTimestamp ShardIteratorType = "AT_TIMESTAMP"
+
 	// The stream is being created. Upon receiving a CreateStream request,
 	// Amazon Kinesis immediately returns and sets StreamStatus to CREATING.
 	StreamStatusCreating StreamStatus = "CREATING"
@@ -85,14 +91,57 @@ type StreamDescriptionT struct {
 	TableName            string
 }
 
+// OpenShards returns the shards that are still accepting writes, i.e.
+// those whose SequenceNumberRange has no EndingSequenceNumber.
+func (d *StreamDescriptionT) OpenShards() []ShardT {
+	var open []ShardT
+	for _, shard := range d.Shards {
+		if shard.SequenceNumberRange.EndingSequenceNumber == "" {
+			open = append(open, shard)
+		}
+	}
+	return open
+}
+
+// ClosedShards returns the shards that have stopped accepting writes,
+// typically because they were split or merged into other shards.
+func (d *StreamDescriptionT) ClosedShards() []ShardT {
+	var closed []ShardT
+	for _, shard := range d.Shards {
+		if shard.SequenceNumberRange.EndingSequenceNumber != "" {
+			closed = append(closed, shard)
+		}
+	}
+	return closed
+}
+
+// Children returns the shards, if any, that were created when shardId
+// was split or merged.
+func (d *StreamDescriptionT) Children(shardId string) []ShardT {
+	var children []ShardT
+	for _, shard := range d.Shards {
+		if shard.ParentShardId == shardId {
+			children = append(children, shard)
+		}
+	}
+	return children
+}
+
 // Represents the output of a DescribeStream operation.
 type describeStreamResponse struct {
 	StreamDescription StreamDescriptionT
 }
 
 func (t *Table) DescribeStream(streamId string) (*StreamDescriptionT, error) {
+	return t.describeStream(streamId, "")
+}
+
+// describeStream issues a single DescribeStream call, optionally resuming
+// from exclusiveStartShardId when paginating through a shard list too
+// large to return in one response.
+func (t *Table) describeStream(streamId, exclusiveStartShardId string) (*StreamDescriptionT, error) {
 	q := NewEmptyQuery()
-	q.AddDescribeStreamRequest(streamId)
+	q.AddDescribeStreamRequest(streamId, exclusiveStartShardId)
 
 	jsonResponse, err := t.Server.queryServer(target("DescribeStream"), q)
 	if err != nil {
@@ -108,9 +157,53 @@ func (t *Table) DescribeStream(streamId string) (*StreamDescriptionT, error) {
 	return &r.StreamDescription, nil
 }
 
+// DescribeStreamAll returns the full StreamDescriptionT for streamId,
+// transparently paginating via ExclusiveStartShardId/LastEvaluatedShardId
+// so callers don't have to loop over DescribeStream themselves.
+func (t *Table) DescribeStreamAll(streamId string) (*StreamDescriptionT, error) {
+	return describeStreamPages(func(exclusiveStartShardId string) (*StreamDescriptionT, error) {
+		return t.describeStream(streamId, exclusiveStartShardId)
+	})
+}
+
+// describeStreamPages merges successive DescribeStream pages, fetched
+// via fetch, into a single StreamDescriptionT. It is split out from
+// DescribeStreamAll so the pagination/merge logic can be unit tested
+// without a live server.
+func describeStreamPages(fetch func(exclusiveStartShardId string) (*StreamDescriptionT, error)) (*StreamDescriptionT, error) {
+	desc, err := fetch("")
+	if err != nil {
+		return nil, err
+	}
+
+	for desc.LastEvaluatedShardId != "" {
+		next, err := fetch(desc.LastEvaluatedShardId)
+		if err != nil {
+			return nil, err
+		}
+		desc.Shards = append(desc.Shards, next.Shards...)
+		desc.LastEvaluatedShardId = next.LastEvaluatedShardId
+	}
+
+	return desc, nil
+}
+
+// StreamSpec identifies a single DynamoDB Stream, as returned by
+// ListAllStreams (ListStreams returns only the stream ARNs). Unlike a
+// Table, it isn't scoped to a
+// particular table.
+type StreamSpec struct {
+	StreamArn   string
+	TableName   string
+	StreamLabel string
+}
+
+// listStreamsResponse is the ListStreams wire shape: a page of Streams
+// plus, when the account has more streams than fit in one page, the
+// arn to resume from via ExclusiveStartStreamArn.
 type listStreamsResponse struct {
-	LastEvaluatedStreamId string
-	StreamIds             []string
+	LastEvaluatedStreamArn string
+	Streams                []StreamSpec
 }
 
 func (t *Table) ListStreams() ([]string, error) {
@@ -128,7 +221,65 @@ func (t *Table) ListStreams() ([]string, error) {
 		return nil, err
 	}
 
-	return r.StreamIds, nil
+	streamIds := make([]string, len(r.Streams))
+	for i, stream := range r.Streams {
+		streamIds[i] = stream.StreamArn
+	}
+	return streamIds, nil
+}
+
+// ListAllStreams enumerates streams across the account, up to limit
+// streams per request page, transparently following
+// ExclusiveStartStreamArn/LastEvaluatedStreamArn pagination so the
+// returned slice holds every matching stream. Pass "" for tableName to
+// enumerate streams across every table in the account instead of a
+// single one.
+func (s *Server) ListAllStreams(tableName string, limit int) ([]StreamSpec, error) {
+	return listStreamsPages(func(exclusiveStartStreamArn string) (*listStreamsResponse, error) {
+		q := NewEmptyQuery()
+		if tableName != "" {
+			q.addTableByName(tableName)
+		}
+		if limit > 0 {
+			q.AddLimit(limit)
+		}
+		if exclusiveStartStreamArn != "" {
+			q.AddExclusiveStartStreamArn(exclusiveStartStreamArn)
+		}
+
+		jsonResponse, err := s.queryServer(target("ListStreams"), q)
+		if err != nil {
+			return nil, err
+		}
+
+		var r listStreamsResponse
+		if err := json.Unmarshal(jsonResponse, &r); err != nil {
+			return nil, err
+		}
+		return &r, nil
+	})
+}
+
+// listStreamsPages accumulates successive ListStreams pages, fetched
+// via fetch, into a single slice of StreamSpec. It is split out from
+// ListAllStreams so the pagination logic can be unit tested without a
+// live server.
+func listStreamsPages(fetch func(exclusiveStartStreamArn string) (*listStreamsResponse, error)) ([]StreamSpec, error) {
+	var streams []StreamSpec
+	var lastStreamArn string
+
+	for {
+		r, err := fetch(lastStreamArn)
+		if err != nil {
+			return nil, err
+		}
+		streams = append(streams, r.Streams...)
+
+		if r.LastEvaluatedStreamArn == "" {
+			return streams, nil
+		}
+		lastStreamArn = r.LastEvaluatedStreamArn
+	}
 }
 
 type getShardIteratorResponse struct {
@@ -136,8 +287,37 @@ type getShardIteratorResponse struct {
 }
 
 func (t *Table) GetShardIteratorWithSeqNumber(streamId, shardId string, shardIteratorType ShardIteratorType, seqNumber string) (string, error) {
+	return t.getShardIterator(streamId, shardId, shardIteratorType, seqNumber, time.Time{})
+}
+
+// errAtTimestampUnsupported is returned by GetShardIteratorAtTimestamp
+// without contacting the server, since DynamoDB Streams'
+// GetShardIterator rejects AT_TIMESTAMP with a ValidationException for
+// every stream reachable through a *Table.
+var errAtTimestampUnsupported = errors.New("dynamodb: AT_TIMESTAMP shard iterators are a Kinesis Data Streams feature; DynamoDB Streams' GetShardIterator does not support them")
+
+// GetShardIteratorAtTimestamp would return a shard iterator starting at
+// the first record at or after ts, using the Kinesis AT_TIMESTAMP
+// iterator type, letting a consumer resume from a wall-clock position
+// instead of a sequence number.
+//
+// AT_TIMESTAMP is a Kinesis Data Streams feature: DynamoDB Streams'
+// GetShardIterator endpoint rejects it with a ValidationException, so
+// there is no iterator this method could ever successfully return for
+// a stream reached through *Table. Rather than make a round trip that
+// is guaranteed to be rejected by the server, it fails fast with
+// errAtTimestampUnsupported.
+func (t *Table) GetShardIteratorAtTimestamp(streamId, shardId string, ts time.Time) (string, error) {
+	return "", errAtTimestampUnsupported
+}
+
+func (t *Table) GetShardIterator(streamId, shardId string, shardIteratorType ShardIteratorType) (string, error) {
+	return t.GetShardIteratorWithSeqNumber(streamId, shardId, shardIteratorType, "")
+}
+
+func (t *Table) getShardIterator(streamId, shardId string, shardIteratorType ShardIteratorType, seqNumber string, ts time.Time) (string, error) {
 	q := NewEmptyQuery()
-	q.AddGetShardIteratorRequest(streamId, shardId, string(shardIteratorType), seqNumber)
+	q.AddGetShardIteratorRequest(streamId, shardId, string(shardIteratorType), seqNumber, ts)
 
 	jsonResponse, err := t.Server.queryServer(target("GetShardIterator"), q)
 	if err != nil {
@@ -153,26 +333,132 @@ func (t *Table) GetShardIteratorWithSeqNumber(streamId, shardId string, shardIte
 	return r.ShardIterator, nil
 }
 
-func (t *Table) GetShardIterator(streamId, shardId string, shardIteratorType ShardIteratorType) (string, error) {
-	return t.GetShardIteratorWithSeqNumber(streamId, shardId, shardIteratorType, "")
+// OperationType describes the kind of write that produced a stream
+// record.
+type OperationType string
+
+const (
+	// A new item was added to the table.
+	OperationTypeInsert OperationType = "INSERT"
+
+	// One or more of an existing item's attributes were modified.
+	OperationTypeModify OperationType = "MODIFY"
+
+	// The item was deleted from the table.
+	OperationTypeRemove OperationType = "REMOVE"
+)
+
+// Identity describes the principal that made the change which produced
+// a record. It is only populated for changes made by DynamoDB itself,
+// such as an item expiring via Time To Live, in which case Type is
+// "Service" and PrincipalId is "dynamodb.amazonaws.com". User-driven
+// writes leave it nil.
+type Identity struct {
+	PrincipalId string
+	Type        string
 }
 
 type StreamRecordT struct {
-	Keys           dynamizer.DynamoItem
-	NewImage       dynamizer.DynamoItem
-	OldImage       dynamizer.DynamoItem
-	SequenceNumber string
-	SizeBytes      int64
-	StreamViewType string
+	// The time, to the nearest second, at which this item's
+	// modification was recorded in the stream.
+	ApproximateCreationDateTime time.Time
+	Keys                        dynamizer.DynamoItem
+	NewImage                    dynamizer.DynamoItem
+	OldImage                    dynamizer.DynamoItem
+	SequenceNumber              string
+	SizeBytes                   int64
+	StreamViewType              string
 }
 
 type RecordT struct {
-	AwsRegion    string        `json:awsRegion`
-	DynamoDB     StreamRecordT `json:dynamodb`
-	EventID      string        `json:eventID`
-	EventName    string        `json:eventName`
-	EventSource  string        `json:eventSource`
-	EventVersion string        `json:eventVersion`
+	AwsRegion    string
+	DynamoDB     StreamRecordT
+	EventID      string
+	EventName    OperationType
+	EventSource  string
+	EventVersion string
+
+	// The principal responsible for the write, when known. See Identity.
+	UserIdentity *Identity
+}
+
+// rawRecordT mirrors the JSON envelope returned by GetRecords: the
+// record-level fields use camelCase keys while the nested "dynamodb"
+// object keeps DynamoDB's usual PascalCase attribute names, and
+// ApproximateCreationDateTime arrives as a Unix timestamp in seconds.
+type rawRecordT struct {
+	AwsRegion    string          `json:"awsRegion"`
+	DynamoDB     json.RawMessage `json:"dynamodb"`
+	EventID      string          `json:"eventID"`
+	EventName    OperationType   `json:"eventName"`
+	EventSource  string          `json:"eventSource"`
+	EventVersion string          `json:"eventVersion"`
+	UserIdentity *Identity       `json:"userIdentity"`
+}
+
+type rawStreamRecordT struct {
+	ApproximateCreationDateTime float64              `json:"ApproximateCreationDateTime"`
+	Keys                        dynamizer.DynamoItem `json:"Keys"`
+	NewImage                    dynamizer.DynamoItem `json:"NewImage"`
+	OldImage                    dynamizer.DynamoItem `json:"OldImage"`
+	SequenceNumber              string               `json:"SequenceNumber"`
+	SizeBytes                   int64                `json:"SizeBytes"`
+	StreamViewType              string               `json:"StreamViewType"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler. RecordT's fields carry no
+// JSON tags, so this decodes through the explicitly tagged shadow types
+// above instead and also converts ApproximateCreationDateTime from its
+// Unix-timestamp wire representation to a time.Time.
+func (r *RecordT) UnmarshalJSON(data []byte) error {
+	var raw rawRecordT
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	var streamRecord rawStreamRecordT
+	if len(raw.DynamoDB) > 0 {
+		if err := json.Unmarshal(raw.DynamoDB, &streamRecord); err != nil {
+			return err
+		}
+	}
+
+	r.AwsRegion = raw.AwsRegion
+	r.EventID = raw.EventID
+	r.EventName = raw.EventName
+	r.EventSource = raw.EventSource
+	r.EventVersion = raw.EventVersion
+	r.UserIdentity = raw.UserIdentity
+	r.DynamoDB = StreamRecordT{
+		Keys:           streamRecord.Keys,
+		NewImage:       streamRecord.NewImage,
+		OldImage:       streamRecord.OldImage,
+		SequenceNumber: streamRecord.SequenceNumber,
+		SizeBytes:      streamRecord.SizeBytes,
+		StreamViewType: streamRecord.StreamViewType,
+	}
+	// Leave ApproximateCreationDateTime as the zero time.Time when the
+	// field is absent (e.g. KEYS_ONLY records) rather than mapping it to
+	// the Unix epoch, so callers can rely on time.Time.IsZero().
+	if streamRecord.ApproximateCreationDateTime != 0 {
+		r.DynamoDB.ApproximateCreationDateTime = time.Unix(int64(streamRecord.ApproximateCreationDateTime), 0)
+	}
+	return nil
+}
+
+// DecodeNewImage decodes the record's NewImage into dst using the
+// dynamizer package. dst must be a pointer to a struct tagged for use
+// with dynamizer. It works the same whether the record was produced by
+// GetRecords or decoded from a Lambda event via the streamsevent
+// package, since both populate NewImage as a dynamizer.DynamoItem.
+func (s StreamRecordT) DecodeNewImage(dst interface{}) error {
+	return dynamizer.Unmarshal(s.NewImage, dst)
+}
+
+// DecodeOldImage decodes the record's OldImage into dst using the
+// dynamizer package. See DecodeNewImage.
+func (s StreamRecordT) DecodeOldImage(dst interface{}) error {
+	return dynamizer.Unmarshal(s.OldImage, dst)
 }
 
 type getRecordsResponse struct {
@@ -0,0 +1,78 @@
+package dynamodb
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestRecordTUnmarshalJSON(t *testing.T) {
+	const payload = `{
+		"awsRegion": "us-east-1",
+		"eventID": "1",
+		"eventName": "MODIFY",
+		"eventSource": "aws:dynamodb",
+		"eventVersion": "1.1",
+		"userIdentity": {"principalId": "dynamodb.amazonaws.com", "type": "Service"},
+		"dynamodb": {
+			"ApproximateCreationDateTime": 1430000000,
+			"Keys": {},
+			"NewImage": {},
+			"SequenceNumber": "123",
+			"SizeBytes": 26,
+			"StreamViewType": "NEW_AND_OLD_IMAGES"
+		}
+	}`
+
+	var rec RecordT
+	if err := json.Unmarshal([]byte(payload), &rec); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	if rec.AwsRegion != "us-east-1" {
+		t.Errorf("AwsRegion = %q, want us-east-1", rec.AwsRegion)
+	}
+	if rec.EventName != OperationTypeModify {
+		t.Errorf("EventName = %q, want %q", rec.EventName, OperationTypeModify)
+	}
+	if rec.UserIdentity == nil || rec.UserIdentity.Type != "Service" {
+		t.Errorf("UserIdentity = %+v, want a Service identity", rec.UserIdentity)
+	}
+	if rec.DynamoDB.SequenceNumber != "123" {
+		t.Errorf("SequenceNumber = %q, want 123", rec.DynamoDB.SequenceNumber)
+	}
+
+	want := time.Unix(1430000000, 0)
+	if !rec.DynamoDB.ApproximateCreationDateTime.Equal(want) {
+		t.Errorf("ApproximateCreationDateTime = %v, want %v", rec.DynamoDB.ApproximateCreationDateTime, want)
+	}
+}
+
+func TestRecordTUnmarshalJSONZeroCreationDateTime(t *testing.T) {
+	const payload = `{
+		"eventName": "REMOVE",
+		"dynamodb": {
+			"Keys": {},
+			"SequenceNumber": "456",
+			"StreamViewType": "KEYS_ONLY"
+		}
+	}`
+
+	var rec RecordT
+	if err := json.Unmarshal([]byte(payload), &rec); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	if !rec.DynamoDB.ApproximateCreationDateTime.IsZero() {
+		t.Errorf("ApproximateCreationDateTime = %v, want the zero value", rec.DynamoDB.ApproximateCreationDateTime)
+	}
+}
+
+func TestGetShardIteratorAtTimestampUnsupported(t *testing.T) {
+	var table *Table // GetShardIteratorAtTimestamp must fail before touching t.Server.
+
+	_, err := table.GetShardIteratorAtTimestamp("stream-id", "shard-id", time.Now())
+	if err != errAtTimestampUnsupported {
+		t.Fatalf("err = %v, want errAtTimestampUnsupported", err)
+	}
+}
@@ -0,0 +1,29 @@
+// Package streamsevent decodes the JSON envelope AWS Lambda delivers to
+// a function subscribed to a DynamoDB Streams event source mapping
+// into the same *dynamodb.RecordT representation Table.GetRecords
+// returns, so consumer code can process records the same way
+// regardless of whether they arrived via a direct GetRecords call or a
+// Lambda invocation.
+package streamsevent
+
+import (
+	"encoding/json"
+
+	"github.com/crowdmob/goamz/dynamodb"
+)
+
+// Event is the top-level payload Lambda passes to a function
+// triggered by a DynamoDB Streams event source mapping.
+type Event struct {
+	Records []*dynamodb.RecordT
+}
+
+// Unmarshal decodes a Lambda DynamoDB Streams event payload into its
+// records.
+func Unmarshal(data []byte) ([]*dynamodb.RecordT, error) {
+	var event Event
+	if err := json.Unmarshal(data, &event); err != nil {
+		return nil, err
+	}
+	return event.Records, nil
+}
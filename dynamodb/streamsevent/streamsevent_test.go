@@ -0,0 +1,65 @@
+package streamsevent
+
+import (
+	"testing"
+
+	"github.com/crowdmob/goamz/dynamodb"
+)
+
+func TestUnmarshal(t *testing.T) {
+	const payload = `{
+		"Records": [
+			{
+				"eventID": "1",
+				"eventName": "INSERT",
+				"eventSource": "aws:dynamodb",
+				"eventVersion": "1.1",
+				"awsRegion": "us-east-1",
+				"dynamodb": {
+					"ApproximateCreationDateTime": 1480642020,
+					"Keys": {},
+					"NewImage": {},
+					"SequenceNumber": "111",
+					"SizeBytes": 26,
+					"StreamViewType": "NEW_AND_OLD_IMAGES"
+				}
+			},
+			{
+				"eventID": "2",
+				"eventName": "REMOVE",
+				"eventSource": "aws:dynamodb",
+				"eventVersion": "1.1",
+				"awsRegion": "us-east-1",
+				"userIdentity": {"principalId": "dynamodb.amazonaws.com", "type": "Service"},
+				"dynamodb": {
+					"Keys": {},
+					"OldImage": {},
+					"SequenceNumber": "222",
+					"StreamViewType": "OLD_IMAGE"
+				}
+			}
+		]
+	}`
+
+	records, err := Unmarshal([]byte(payload))
+	if err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("len(records) = %d, want 2", len(records))
+	}
+
+	if records[0].EventName != dynamodb.OperationTypeInsert {
+		t.Errorf("records[0].EventName = %q, want %q", records[0].EventName, dynamodb.OperationTypeInsert)
+	}
+	if records[0].DynamoDB.SequenceNumber != "111" {
+		t.Errorf("records[0].DynamoDB.SequenceNumber = %q, want 111", records[0].DynamoDB.SequenceNumber)
+	}
+
+	if records[1].EventName != dynamodb.OperationTypeRemove {
+		t.Errorf("records[1].EventName = %q, want %q", records[1].EventName, dynamodb.OperationTypeRemove)
+	}
+	if records[1].UserIdentity == nil || records[1].UserIdentity.Type != "Service" {
+		t.Errorf("records[1].UserIdentity = %+v, want a Service identity", records[1].UserIdentity)
+	}
+}